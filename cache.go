@@ -0,0 +1,70 @@
+package cas
+
+import (
+	"sync"
+	"time"
+)
+
+// ticketCacheEntry holds a cached validation outcome, including a negative
+// (error) result, so that a rejected ticket is not re-validated on every
+// redirect either.
+type ticketCacheEntry struct {
+	response  *AuthenticationResponse
+	err       error
+	expiresAt time.Time
+}
+
+// ticketCache is a small in-memory cache of ticket validation results, keyed
+// by the ticket string, used to absorb duplicate validations of the same
+// ticket seen during browser redirects.
+type ticketCache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	entries    map[string]ticketCacheEntry
+}
+
+func newTicketCache(ttl time.Duration, maxEntries int) *ticketCache {
+	return &ticketCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]ticketCacheEntry),
+	}
+}
+
+func (c *ticketCache) get(ticket string) (*AuthenticationResponse, error, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[ticket]
+	if !ok {
+		return nil, nil, false
+	}
+
+	if time.Now().After(entry.expiresAt) {
+		delete(c.entries, ticket)
+		return nil, nil, false
+	}
+
+	return entry.response, entry.err, true
+}
+
+func (c *ticketCache) put(ticket string, response *AuthenticationResponse, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, exists := c.entries[ticket]; !exists && len(c.entries) >= c.maxEntries {
+		// Evict an arbitrary entry to make room. Go's map iteration order
+		// is randomised, which is sufficient for a best-effort cache.
+		for k := range c.entries {
+			delete(c.entries, k)
+			break
+		}
+	}
+
+	c.entries[ticket] = ticketCacheEntry{
+		response:  response,
+		err:       err,
+		expiresAt: time.Now().Add(c.ttl),
+	}
+}
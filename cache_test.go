@@ -0,0 +1,77 @@
+package cas
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTicketCacheGetMiss(t *testing.T) {
+	c := newTicketCache(time.Minute, 10)
+
+	if _, _, ok := c.get("ST-1"); ok {
+		t.Fatal("get() on empty cache = ok, want miss")
+	}
+}
+
+func TestTicketCachePutGet(t *testing.T) {
+	c := newTicketCache(time.Minute, 10)
+	want := &AuthenticationResponse{User: "jsmith", Success: true}
+
+	c.put("ST-1", want, nil)
+
+	got, err, ok := c.get("ST-1")
+	if !ok {
+		t.Fatal("get() after put = miss, want hit")
+	}
+	if err != nil {
+		t.Errorf("get() error = %v, want nil", err)
+	}
+	if got != want {
+		t.Errorf("get() = %v, want %v", got, want)
+	}
+}
+
+func TestTicketCacheCachesNegativeResult(t *testing.T) {
+	c := newTicketCache(time.Minute, 10)
+	wantErr := errors.New("cas: validation failure")
+
+	c.put("ST-1", nil, wantErr)
+
+	got, err, ok := c.get("ST-1")
+	if !ok {
+		t.Fatal("get() after put = miss, want hit")
+	}
+	if got != nil {
+		t.Errorf("get() response = %v, want nil", got)
+	}
+	if err != wantErr {
+		t.Errorf("get() error = %v, want %v", err, wantErr)
+	}
+}
+
+func TestTicketCacheExpiry(t *testing.T) {
+	c := newTicketCache(time.Nanosecond, 10)
+	c.put("ST-1", &AuthenticationResponse{User: "jsmith"}, nil)
+
+	time.Sleep(time.Millisecond)
+
+	if _, _, ok := c.get("ST-1"); ok {
+		t.Fatal("get() after TTL expiry = hit, want miss")
+	}
+}
+
+func TestTicketCacheEvictsWhenFull(t *testing.T) {
+	c := newTicketCache(time.Minute, 1)
+
+	c.put("ST-1", &AuthenticationResponse{User: "first"}, nil)
+	c.put("ST-2", &AuthenticationResponse{User: "second"}, nil)
+
+	if len(c.entries) != 1 {
+		t.Fatalf("len(entries) = %d, want 1 after eviction", len(c.entries))
+	}
+
+	if _, _, ok := c.get("ST-2"); !ok {
+		t.Fatal("get(ST-2) after put = miss, want hit for the most recently inserted entry")
+	}
+}
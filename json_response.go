@@ -0,0 +1,84 @@
+package cas
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+type jsonServiceResponse struct {
+	ServiceResponse struct {
+		AuthenticationFailure *jsonAuthenticationFailure `json:"authenticationFailure"`
+		AuthenticationSuccess *jsonAuthenticationSuccess `json:"authenticationSuccess"`
+	} `json:"serviceResponse"`
+}
+
+type jsonAuthenticationFailure struct {
+	Code        string `json:"code"`
+	Description string `json:"description"`
+}
+
+type jsonAuthenticationSuccess struct {
+	User                string              `json:"user"`
+	ProxyGrantingTicket string              `json:"proxyGrantingTicket"`
+	Proxies             []string            `json:"proxies"`
+	Attributes          map[string][]string `json:"attributes"`
+}
+
+// parseJSONServiceResponse parses the JSON equivalent of a CAS
+// <cas:serviceResponse> document, as returned when format=JSON is requested,
+// into an AuthenticationResponse. Unlike the XML response, CAS JSON emits
+// RFC3339 timestamps directly, so no timezone cleanup is required.
+func parseJSONServiceResponse(body []byte) (*AuthenticationResponse, error) {
+	resp := &jsonServiceResponse{}
+	if err := json.Unmarshal(body, resp); err != nil {
+		return nil, fmt.Errorf("cas: error parsing json service response: %v", err)
+	}
+
+	if resp.ServiceResponse.AuthenticationFailure != nil {
+		f := resp.ServiceResponse.AuthenticationFailure
+		return nil, fmt.Errorf("cas: validation failure: %v: %v", f.Code, f.Description)
+	}
+
+	success := resp.ServiceResponse.AuthenticationSuccess
+	if success == nil {
+		return nil, fmt.Errorf("cas: service response contains neither success nor failure")
+	}
+
+	result := &AuthenticationResponse{
+		User:                success.User,
+		Success:             true,
+		ProxyGrantingTicket: success.ProxyGrantingTicket,
+		Proxies:             success.Proxies,
+		Attributes:          make(map[string][]string),
+	}
+
+	// JSON object key order is not preserved by encoding/json, so
+	// AttributeNames is built in sorted order here rather than receipt
+	// order (see the AttributeNames doc comment on AuthenticationResponse).
+	for name := range success.Attributes {
+		result.AttributeNames = append(result.AttributeNames, name)
+	}
+	sort.Strings(result.AttributeNames)
+
+	for name, values := range success.Attributes {
+		result.Attributes[name] = values
+
+		if len(values) == 0 {
+			continue
+		}
+
+		switch name {
+		case "authenticationDate":
+			result.AuthenticationDate = values[0]
+		case "isFromNewLogin":
+			result.IsFromNewLogin = values[0] == "true"
+		case "longTermAuthenticationRequestTokenUsed":
+			result.LongTermAuthenticationRequestTokenUsed = values[0] == "true"
+		case "memberOf":
+			result.MemberOf = values
+		}
+	}
+
+	return result, nil
+}
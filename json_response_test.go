@@ -0,0 +1,90 @@
+package cas
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseJSONServiceResponseSuccess(t *testing.T) {
+	body := []byte(`{
+  "serviceResponse": {
+    "authenticationSuccess": {
+      "user": "jsmith",
+      "attributes": {
+        "authenticationDate": ["2026-07-29T12:00:00Z"],
+        "isFromNewLogin": ["true"],
+        "memberOf": ["admins", "developers"]
+      }
+    }
+  }
+}`)
+
+	resp, err := parseJSONServiceResponse(body)
+	if err != nil {
+		t.Fatalf("parseJSONServiceResponse() error = %v", err)
+	}
+
+	if !resp.Success || resp.User != "jsmith" {
+		t.Fatalf("parseJSONServiceResponse() = %+v, want success for jsmith", resp)
+	}
+
+	if !resp.IsFromNewLogin {
+		t.Errorf("IsFromNewLogin = false, want true")
+	}
+
+	wantMemberOf := []string{"admins", "developers"}
+	if !reflect.DeepEqual(resp.MemberOf, wantMemberOf) {
+		t.Errorf("MemberOf = %v, want %v", resp.MemberOf, wantMemberOf)
+	}
+
+	wantNames := []string{"authenticationDate", "isFromNewLogin", "memberOf"}
+	if !reflect.DeepEqual(resp.AttributeNames, wantNames) {
+		t.Errorf("AttributeNames = %v, want %v (sorted)", resp.AttributeNames, wantNames)
+	}
+}
+
+func TestParseJSONServiceResponseNoAttributes(t *testing.T) {
+	body := []byte(`{
+  "serviceResponse": {
+    "authenticationSuccess": {
+      "user": "jsmith"
+    }
+  }
+}`)
+
+	resp, err := parseJSONServiceResponse(body)
+	if err != nil {
+		t.Fatalf("parseJSONServiceResponse() error = %v", err)
+	}
+
+	if resp.Attributes == nil {
+		t.Fatal("Attributes = nil, want non-nil empty map")
+	}
+
+	if len(resp.Attributes) != 0 {
+		t.Errorf("Attributes = %v, want empty", resp.Attributes)
+	}
+}
+
+func TestParseJSONServiceResponseFailure(t *testing.T) {
+	body := []byte(`{
+  "serviceResponse": {
+    "authenticationFailure": {
+      "code": "INVALID_TICKET",
+      "description": "Ticket ST-1 not recognized"
+    }
+  }
+}`)
+
+	_, err := parseJSONServiceResponse(body)
+	if err == nil {
+		t.Fatal("parseJSONServiceResponse() error = nil, want failure error")
+	}
+}
+
+func TestParseJSONServiceResponseMalformed(t *testing.T) {
+	_, err := parseJSONServiceResponse([]byte(`{not json`))
+	if err == nil {
+		t.Fatal("parseJSONServiceResponse() error = nil, want parse error for malformed JSON")
+	}
+}
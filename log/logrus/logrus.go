@@ -0,0 +1,39 @@
+// Package logrus adapts sirupsen/logrus to the cas.Logger interface.
+package logrus
+
+import (
+	"github.com/sirupsen/logrus"
+
+	"github.com/inforix/cas-client-go"
+)
+
+// Logger wraps a logrus.FieldLogger as a cas.Logger.
+type Logger struct {
+	logger logrus.FieldLogger
+}
+
+// New returns a cas.Logger backed by logger.
+func New(logger logrus.FieldLogger) *Logger {
+	return &Logger{logger: logger}
+}
+
+func (l *Logger) Debug(msg string, kv ...interface{}) { l.fields(kv).Debug(msg) }
+func (l *Logger) Info(msg string, kv ...interface{})  { l.fields(kv).Info(msg) }
+func (l *Logger) Warn(msg string, kv ...interface{})  { l.fields(kv).Warn(msg) }
+func (l *Logger) Error(msg string, kv ...interface{}) { l.fields(kv).Error(msg) }
+
+func (l *Logger) fields(kv []interface{}) *logrus.Entry {
+	fields := make(logrus.Fields, len(kv)/2)
+
+	for i := 0; i+1 < len(kv); i += 2 {
+		key, ok := kv[i].(string)
+		if !ok {
+			continue
+		}
+		fields[key] = kv[i+1]
+	}
+
+	return l.logger.WithFields(fields)
+}
+
+var _ cas.Logger = (*Logger)(nil)
@@ -0,0 +1,26 @@
+// Package slog adapts the standard library's log/slog to the cas.Logger
+// interface.
+package slog
+
+import (
+	"log/slog"
+
+	"github.com/inforix/cas-client-go"
+)
+
+// Logger wraps an *slog.Logger as a cas.Logger.
+type Logger struct {
+	logger *slog.Logger
+}
+
+// New returns a cas.Logger backed by logger.
+func New(logger *slog.Logger) *Logger {
+	return &Logger{logger: logger}
+}
+
+func (l *Logger) Debug(msg string, kv ...interface{}) { l.logger.Debug(msg, kv...) }
+func (l *Logger) Info(msg string, kv ...interface{})  { l.logger.Info(msg, kv...) }
+func (l *Logger) Warn(msg string, kv ...interface{})  { l.logger.Warn(msg, kv...) }
+func (l *Logger) Error(msg string, kv ...interface{}) { l.logger.Error(msg, kv...) }
+
+var _ cas.Logger = (*Logger)(nil)
@@ -0,0 +1,25 @@
+// Package zap adapts uber-go/zap to the cas.Logger interface.
+package zap
+
+import (
+	"go.uber.org/zap"
+
+	"github.com/inforix/cas-client-go"
+)
+
+// Logger wraps a *zap.SugaredLogger as a cas.Logger.
+type Logger struct {
+	logger *zap.SugaredLogger
+}
+
+// New returns a cas.Logger backed by logger.
+func New(logger *zap.SugaredLogger) *Logger {
+	return &Logger{logger: logger}
+}
+
+func (l *Logger) Debug(msg string, kv ...interface{}) { l.logger.Debugw(msg, kv...) }
+func (l *Logger) Info(msg string, kv ...interface{})  { l.logger.Infow(msg, kv...) }
+func (l *Logger) Warn(msg string, kv ...interface{})  { l.logger.Warnw(msg, kv...) }
+func (l *Logger) Error(msg string, kv ...interface{}) { l.logger.Errorw(msg, kv...) }
+
+var _ cas.Logger = (*Logger)(nil)
@@ -0,0 +1,22 @@
+package cas
+
+// Logger is the logging interface required by ServiceTicketValidator.
+// Implementations receive a message plus an optional list of alternating
+// key-value pairs, mirroring the structured logging style of log/slog,
+// logrus, and zap's SugaredLogger. Callers inject their application's
+// logger via WithLogger; by default ServiceTicketValidator logs nothing.
+type Logger interface {
+	Debug(msg string, kv ...interface{})
+	Info(msg string, kv ...interface{})
+	Warn(msg string, kv ...interface{})
+	Error(msg string, kv ...interface{})
+}
+
+// nopLogger is the default Logger: it discards everything. Unlike glog, it
+// registers no global flags and never writes to disk.
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...interface{}) {}
+func (nopLogger) Info(string, ...interface{})  {}
+func (nopLogger) Warn(string, ...interface{})  {}
+func (nopLogger) Error(string, ...interface{}) {}
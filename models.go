@@ -0,0 +1,129 @@
+package cas
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ProtocolVersion identifies which CAS protocol revision a
+// ServiceTicketValidator should speak.
+type ProtocolVersion int
+
+const (
+	// ProtocolVersionUnspecified lets the validator probe the CAS server,
+	// preferring CAS 3.0, then falling back to CAS 2.0 and CAS 1.0.
+	ProtocolVersionUnspecified ProtocolVersion = iota
+	// ProtocolVersionCAS1 pins validation to the CAS 1.0 /validate endpoint.
+	ProtocolVersionCAS1
+	// ProtocolVersionCAS2 pins validation to the CAS 2.0 /serviceValidate endpoint.
+	ProtocolVersionCAS2
+	// ProtocolVersionCAS3 pins validation to the CAS 3.0 /p3/serviceValidate endpoint.
+	ProtocolVersionCAS3
+)
+
+// AuthenticationResponse is the response to a ticket validation request.
+type AuthenticationResponse struct {
+	User    string
+	Success bool
+
+	AuthenticationDate                     string
+	IsFromNewLogin                         bool
+	LongTermAuthenticationRequestTokenUsed bool
+	MemberOf                               []string
+
+	ProxyGrantingTicket string
+	Proxies             []string
+
+	// NotBefore and NotOnOrAfter bound the validity window of a SAML 1.1
+	// assertion, as returned by /samlValidate. They are zero for the
+	// XML/JSON serviceValidate responses, which carry no such window.
+	NotBefore    time.Time
+	NotOnOrAfter time.Time
+
+	// AttributeNames preserves the order in which attributes were
+	// received for the XML serviceValidate response, since Attributes
+	// loses duplicate-name ordering. The JSON serviceValidate response
+	// carries no ordering of its own, so AttributeNames is sorted there
+	// instead of reflecting receipt order.
+	AttributeNames []string
+	// Attributes holds every <cas:attributes> child, keyed by local
+	// (namespace-stripped) element name. Repeated elements, such as
+	// memberOf, accumulate in order.
+	Attributes map[string][]string
+}
+
+type xmlServiceResponse struct {
+	XMLName xml.Name                  `xml:"serviceResponse"`
+	Failure *xmlAuthenticationFailure `xml:"authenticationFailure"`
+	Success *xmlAuthenticationSuccess `xml:"authenticationSuccess"`
+}
+
+type xmlAuthenticationFailure struct {
+	Code  string `xml:"code,attr"`
+	Value string `xml:",chardata"`
+}
+
+type xmlAuthenticationSuccess struct {
+	User                string           `xml:"user"`
+	ProxyGrantingTicket string           `xml:"proxyGrantingTicket"`
+	Proxies             []string         `xml:"proxies>proxy"`
+	Attributes          xmlCasAttributes `xml:"attributes"`
+}
+
+// xmlCasAttributes captures every child of <cas:attributes> generically,
+// since the set of released attributes is deployment-specific.
+type xmlCasAttributes struct {
+	Items []xmlCasAttribute `xml:",any"`
+}
+
+type xmlCasAttribute struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+// ParseServiceResponse parses a CAS 2.0/3.0 <cas:serviceResponse> XML
+// document, as returned by the serviceValidate and p3/serviceValidate
+// endpoints, into an AuthenticationResponse.
+func ParseServiceResponse(body []byte) (*AuthenticationResponse, error) {
+	resp := &xmlServiceResponse{}
+	if err := xml.Unmarshal(body, resp); err != nil {
+		return nil, fmt.Errorf("cas: error parsing service response: %v", err)
+	}
+
+	if resp.Failure != nil {
+		return nil, fmt.Errorf("cas: validation failure: %v: %v", resp.Failure.Code, strings.TrimSpace(resp.Failure.Value))
+	}
+
+	if resp.Success == nil {
+		return nil, fmt.Errorf("cas: service response contains neither success nor failure")
+	}
+
+	success := &AuthenticationResponse{
+		User:                resp.Success.User,
+		Success:             true,
+		ProxyGrantingTicket: resp.Success.ProxyGrantingTicket,
+		Proxies:             resp.Success.Proxies,
+		Attributes:          make(map[string][]string),
+	}
+
+	for _, attr := range resp.Success.Attributes.Items {
+		name := attr.XMLName.Local
+		success.AttributeNames = append(success.AttributeNames, name)
+		success.Attributes[name] = append(success.Attributes[name], attr.Value)
+
+		switch name {
+		case "authenticationDate":
+			success.AuthenticationDate = attr.Value
+		case "isFromNewLogin":
+			success.IsFromNewLogin = attr.Value == "true"
+		case "longTermAuthenticationRequestTokenUsed":
+			success.LongTermAuthenticationRequestTokenUsed = attr.Value == "true"
+		case "memberOf":
+			success.MemberOf = append(success.MemberOf, attr.Value)
+		}
+	}
+
+	return success, nil
+}
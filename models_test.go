@@ -0,0 +1,75 @@
+package cas
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseServiceResponseSuccess(t *testing.T) {
+	body := []byte(`<cas:serviceResponse xmlns:cas="http://www.yale.edu/tp/cas">
+  <cas:authenticationSuccess>
+    <cas:user>jsmith</cas:user>
+    <cas:proxyGrantingTicket>PGTIOU-1</cas:proxyGrantingTicket>
+    <cas:attributes>
+      <cas:authenticationDate>2026-07-29T12:00:00.000Z[Etc/UTC]</cas:authenticationDate>
+      <cas:isFromNewLogin>true</cas:isFromNewLogin>
+      <cas:memberOf>admins</cas:memberOf>
+      <cas:memberOf>developers</cas:memberOf>
+    </cas:attributes>
+  </cas:authenticationSuccess>
+</cas:serviceResponse>`)
+
+	resp, err := ParseServiceResponse(body)
+	if err != nil {
+		t.Fatalf("ParseServiceResponse() error = %v", err)
+	}
+
+	if !resp.Success || resp.User != "jsmith" {
+		t.Fatalf("ParseServiceResponse() = %+v, want success for jsmith", resp)
+	}
+
+	if resp.ProxyGrantingTicket != "PGTIOU-1" {
+		t.Errorf("ProxyGrantingTicket = %q, want PGTIOU-1", resp.ProxyGrantingTicket)
+	}
+
+	if !resp.IsFromNewLogin {
+		t.Errorf("IsFromNewLogin = false, want true")
+	}
+
+	wantMemberOf := []string{"admins", "developers"}
+	if !reflect.DeepEqual(resp.MemberOf, wantMemberOf) {
+		t.Errorf("MemberOf = %v, want %v", resp.MemberOf, wantMemberOf)
+	}
+
+	wantAttrs := []string{"admins", "developers"}
+	if !reflect.DeepEqual(resp.Attributes["memberOf"], wantAttrs) {
+		t.Errorf("Attributes[memberOf] = %v, want %v", resp.Attributes["memberOf"], wantAttrs)
+	}
+}
+
+func TestParseServiceResponseFailure(t *testing.T) {
+	body := []byte(`<cas:serviceResponse xmlns:cas="http://www.yale.edu/tp/cas">
+  <cas:authenticationFailure code="INVALID_TICKET">Ticket ST-1 not recognized</cas:authenticationFailure>
+</cas:serviceResponse>`)
+
+	_, err := ParseServiceResponse(body)
+	if err == nil {
+		t.Fatal("ParseServiceResponse() error = nil, want failure error")
+	}
+}
+
+func TestParseServiceResponseMalformed(t *testing.T) {
+	_, err := ParseServiceResponse([]byte(`<cas:serviceResponse>`))
+	if err == nil {
+		t.Fatal("ParseServiceResponse() error = nil, want parse error for malformed XML")
+	}
+}
+
+func TestParseServiceResponseNeitherSuccessNorFailure(t *testing.T) {
+	body := []byte(`<cas:serviceResponse xmlns:cas="http://www.yale.edu/tp/cas"></cas:serviceResponse>`)
+
+	_, err := ParseServiceResponse(body)
+	if err == nil {
+		t.Fatal("ParseServiceResponse() error = nil, want error for empty response")
+	}
+}
@@ -0,0 +1,91 @@
+package cas
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Option configures a ServiceTicketValidator constructed via
+// NewServiceTicketValidatorWithOptions.
+type Option func(*ServiceTicketValidator)
+
+// WithRetry makes up to attempts requests in total (the first request plus
+// attempts-1 retries) for each ticket validation request, retrying on 5xx
+// responses or network errors, backing off exponentially from base and
+// capped at max. A max of 0 disables the cap.
+func WithRetry(attempts int, base, max time.Duration) Option {
+	return func(validator *ServiceTicketValidator) {
+		validator.retryAttempts = attempts
+		validator.retryBase = base
+		validator.retryMax = max
+	}
+}
+
+// WithTimeout bounds each ticket validation request, including any retries,
+// to at most d.
+func WithTimeout(d time.Duration) Option {
+	return func(validator *ServiceTicketValidator) {
+		validator.timeout = d
+	}
+}
+
+// WithCache enables an in-memory cache of validation results keyed by
+// ticket, holding at most maxEntries entries for up to ttl. This absorbs
+// duplicate validations of the same ticket seen during browser redirects.
+func WithCache(ttl time.Duration, maxEntries int) Option {
+	return func(validator *ServiceTicketValidator) {
+		validator.cache = newTicketCache(ttl, maxEntries)
+	}
+}
+
+// WithUserAgent overrides the default User-Agent header sent with
+// validation requests.
+func WithUserAgent(s string) Option {
+	return func(validator *ServiceTicketValidator) {
+		validator.userAgent = s
+	}
+}
+
+// WithProtocol pins the validator to the given CAS protocol version, as
+// NewServiceTicketValidatorWithProtocol does.
+func WithProtocol(protocol ProtocolVersion) Option {
+	return func(validator *ServiceTicketValidator) {
+		validator.protocol = protocol
+	}
+}
+
+// WithLogger injects a Logger to receive structured validation events. By
+// default a ServiceTicketValidator logs nothing.
+func WithLogger(logger Logger) Option {
+	return func(validator *ServiceTicketValidator) {
+		validator.logger = logger
+	}
+}
+
+// WithTracer injects a Tracer to emit a span around each validation attempt.
+// By default a ServiceTicketValidator does no tracing. See cas/trace/otel
+// for an OpenTelemetry-backed Tracer.
+func WithTracer(tracer Tracer) Option {
+	return func(validator *ServiceTicketValidator) {
+		validator.tracer = tracer
+	}
+}
+
+// NewServiceTicketValidatorWithOptions creates a ServiceTicketValidator
+// configured with the given options, in addition to the default CAS 3.0
+// probing behaviour of NewServiceTicketValidator.
+func NewServiceTicketValidatorWithOptions(client *http.Client, casUrl *url.URL, opts ...Option) *ServiceTicketValidator {
+	validator := &ServiceTicketValidator{
+		client: client,
+		casUrl: casUrl,
+		logger: nopLogger{},
+		tracer: nopTracer{},
+	}
+
+	for _, opt := range opts {
+		opt(validator)
+	}
+
+	return validator
+}
@@ -0,0 +1,207 @@
+package cas
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"time"
+)
+
+const samlRequestTemplate = `<SOAP-ENV:Envelope xmlns:SOAP-ENV="http://schemas.xmlsoap.org/soap/envelope/">
+  <SOAP-ENV:Body>
+    <samlp:Request xmlns:samlp="urn:oasis:names:tc:SAML:1.0:protocol" MajorVersion="1" MinorVersion="1" RequestID="%s" IssueInstant="%s">
+      <samlp:AssertionArtifact>%s</samlp:AssertionArtifact>
+    </samlp:Request>
+  </SOAP-ENV:Body>
+</SOAP-ENV:Envelope>`
+
+// ValidateTicketSAML validates a service ticket against the CAS /samlValidate
+// endpoint, the SAML 1.1 binding exposed by CAS deployments that do not
+// speak the CAS 2.0/3.0 XML protocol. The ticket is passed as a SAML
+// AssertionArtifact in the POST body rather than as a query parameter. It is
+// a thin wrapper around ValidateTicketSAMLContext using context.Background().
+func (validator *ServiceTicketValidator) ValidateTicketSAML(serviceUrl *url.URL, ticket string) (*AuthenticationResponse, error) {
+	return validator.ValidateTicketSAMLContext(context.Background(), serviceUrl, ticket)
+}
+
+// ValidateTicketSAMLContext validates the service ticket via /samlValidate,
+// honouring ctx's deadline and cancellation.
+func (validator *ServiceTicketValidator) ValidateTicketSAMLContext(ctx context.Context, serviceUrl *url.URL, ticket string) (*AuthenticationResponse, error) {
+	ctx, span := validator.trace().Start(ctx, "cas.ValidateTicketSAML")
+	defer span.End()
+	ctx = withSpan(ctx, span)
+
+	span.SetAttribute("cas.protocol", "SAML1.1")
+	span.SetAttribute("cas.service", serviceUrl.String())
+
+	if validator.cache != nil {
+		if success, err, ok := validator.cache.get(ticket); ok {
+			return success, err
+		}
+	}
+
+	success, err := validator.validateTicketSAML(ctx, serviceUrl, ticket)
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	if validator.cache != nil {
+		validator.cache.put(ticket, success, err)
+	}
+
+	return success, err
+}
+
+func (validator *ServiceTicketValidator) validateTicketSAML(ctx context.Context, serviceUrl *url.URL, ticket string) (*AuthenticationResponse, error) {
+	u, err := validator.samlValidateUrl(serviceUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	requestBody, err := samlRequestEnvelope(ticket)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := http.NewRequestWithContext(ctx, "POST", u, bytes.NewBufferString(requestBody))
+	if err != nil {
+		return nil, err
+	}
+
+	r.Header.Add("Content-Type", "text/xml")
+
+	resp, body, err := validator.do(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("cas: validate saml ticket: %v", string(body))
+	}
+
+	validator.log().Debug("received SAML authentication response", "status", resp.StatusCode)
+
+	success, err := parseSAMLServiceResponse(body)
+	if err != nil {
+		validator.log().Error("failed to parse SAML response", "error", err)
+		return nil, err
+	}
+
+	return success, nil
+}
+
+// samlValidateUrl creates the SAML 1.1 validation url. The target service is
+// passed as TARGET, not service, per the SAML binding.
+func (validator *ServiceTicketValidator) samlValidateUrl(serviceUrl *url.URL) (string, error) {
+	u, err := validator.casUrl.Parse(path.Join(validator.casUrl.Path, "samlValidate"))
+	if err != nil {
+		return "", err
+	}
+
+	q := u.Query()
+	q.Add("TARGET", sanitisedURLString(serviceUrl))
+	u.RawQuery = q.Encode()
+
+	return u.String(), nil
+}
+
+func samlRequestID() string {
+	return fmt.Sprintf("_%d", time.Now().UnixNano())
+}
+
+// samlRequestEnvelope builds the SOAP envelope sent to /samlValidate,
+// escaping ticket so that a ticket value cannot splice additional XML into
+// the back-channel validation request.
+func samlRequestEnvelope(ticket string) (string, error) {
+	var escapedTicket bytes.Buffer
+	if err := xml.EscapeText(&escapedTicket, []byte(ticket)); err != nil {
+		return "", fmt.Errorf("cas: error escaping ticket: %v", err)
+	}
+
+	return fmt.Sprintf(samlRequestTemplate, samlRequestID(), time.Now().UTC().Format(time.RFC3339), escapedTicket.String()), nil
+}
+
+type samlEnvelope struct {
+	XMLName xml.Name `xml:"Envelope"`
+	Body    struct {
+		Response struct {
+			Assertion struct {
+				Conditions struct {
+					NotBefore    string `xml:"NotBefore,attr"`
+					NotOnOrAfter string `xml:"NotOnOrAfter,attr"`
+				} `xml:"Conditions"`
+				AuthenticationStatement struct {
+					Subject struct {
+						NameIdentifier string `xml:"NameIdentifier"`
+					} `xml:"Subject"`
+				} `xml:"AuthenticationStatement"`
+				AttributeStatement struct {
+					Attribute []samlAttribute `xml:"Attribute"`
+				} `xml:"AttributeStatement"`
+			} `xml:"Assertion"`
+		} `xml:"Response"`
+	} `xml:"Body"`
+}
+
+type samlAttribute struct {
+	AttributeName  string   `xml:"AttributeName,attr"`
+	AttributeValue []string `xml:"AttributeValue"`
+}
+
+// parseSAMLServiceResponse parses a SAML 1.1 <Response> assertion, as
+// returned by the /samlValidate endpoint, into an AuthenticationResponse.
+func parseSAMLServiceResponse(body []byte) (*AuthenticationResponse, error) {
+	envelope := &samlEnvelope{}
+	if err := xml.Unmarshal(body, envelope); err != nil {
+		return nil, fmt.Errorf("cas: error parsing saml response: %v", err)
+	}
+
+	assertion := envelope.Body.Response.Assertion
+	user := assertion.AuthenticationStatement.Subject.NameIdentifier
+	if user == "" {
+		return nil, fmt.Errorf("cas: saml response contains no NameIdentifier")
+	}
+
+	var notBefore, notOnOrAfter time.Time
+	if assertion.Conditions.NotBefore != "" {
+		parsed, err := time.Parse(time.RFC3339, assertion.Conditions.NotBefore)
+		if err != nil {
+			return nil, fmt.Errorf("cas: error parsing saml assertion NotBefore: %v", err)
+		}
+		notBefore = parsed
+	}
+	if assertion.Conditions.NotOnOrAfter != "" {
+		parsed, err := time.Parse(time.RFC3339, assertion.Conditions.NotOnOrAfter)
+		if err != nil {
+			return nil, fmt.Errorf("cas: error parsing saml assertion NotOnOrAfter: %v", err)
+		}
+		notOnOrAfter = parsed
+	}
+
+	now := time.Now()
+	if !notBefore.IsZero() && now.Before(notBefore) {
+		return nil, fmt.Errorf("cas: saml assertion not yet valid: NotBefore %v", notBefore)
+	}
+	if !notOnOrAfter.IsZero() && !now.Before(notOnOrAfter) {
+		return nil, fmt.Errorf("cas: saml assertion expired: NotOnOrAfter %v", notOnOrAfter)
+	}
+
+	success := &AuthenticationResponse{
+		User:         user,
+		Success:      true,
+		NotBefore:    notBefore,
+		NotOnOrAfter: notOnOrAfter,
+		Attributes:   make(map[string][]string),
+	}
+
+	for _, attr := range assertion.AttributeStatement.Attribute {
+		success.AttributeNames = append(success.AttributeNames, attr.AttributeName)
+		success.Attributes[attr.AttributeName] = attr.AttributeValue
+	}
+
+	return success, nil
+}
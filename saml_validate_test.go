@@ -0,0 +1,144 @@
+package cas
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func samlEnvelopeBody(notBefore, notOnOrAfter string) []byte {
+	return []byte(`<SOAP-ENV:Envelope xmlns:SOAP-ENV="http://schemas.xmlsoap.org/soap/envelope/">
+  <SOAP-ENV:Body>
+    <Response xmlns="urn:oasis:names:tc:SAML:1.0:protocol">
+      <Assertion xmlns="urn:oasis:names:tc:SAML:1.0:assertion">
+        <Conditions NotBefore="` + notBefore + `" NotOnOrAfter="` + notOnOrAfter + `"></Conditions>
+        <AuthenticationStatement>
+          <Subject>
+            <NameIdentifier>jsmith</NameIdentifier>
+          </Subject>
+        </AuthenticationStatement>
+        <AttributeStatement>
+          <Attribute AttributeName="memberOf">
+            <AttributeValue>admins</AttributeValue>
+            <AttributeValue>developers</AttributeValue>
+          </Attribute>
+        </AttributeStatement>
+      </Assertion>
+    </Response>
+  </SOAP-ENV:Body>
+</SOAP-ENV:Envelope>`)
+}
+
+func TestParseSAMLServiceResponseSuccess(t *testing.T) {
+	now := time.Now().UTC()
+	notBefore := now.Add(-time.Hour).Format(time.RFC3339)
+	notOnOrAfter := now.Add(time.Hour).Format(time.RFC3339)
+
+	resp, err := parseSAMLServiceResponse(samlEnvelopeBody(notBefore, notOnOrAfter))
+	if err != nil {
+		t.Fatalf("parseSAMLServiceResponse() error = %v", err)
+	}
+
+	if !resp.Success || resp.User != "jsmith" {
+		t.Fatalf("parseSAMLServiceResponse() = %+v, want success for jsmith", resp)
+	}
+
+	wantValues := []string{"admins", "developers"}
+	if got := resp.Attributes["memberOf"]; !equalStrings(got, wantValues) {
+		t.Errorf("Attributes[memberOf] = %v, want %v", got, wantValues)
+	}
+}
+
+func TestParseSAMLServiceResponseNotYetValid(t *testing.T) {
+	now := time.Now().UTC()
+	notBefore := now.Add(time.Hour).Format(time.RFC3339)
+	notOnOrAfter := now.Add(2 * time.Hour).Format(time.RFC3339)
+
+	_, err := parseSAMLServiceResponse(samlEnvelopeBody(notBefore, notOnOrAfter))
+	if err == nil {
+		t.Fatal("parseSAMLServiceResponse() error = nil, want error for assertion not yet valid")
+	}
+}
+
+func TestParseSAMLServiceResponseExpired(t *testing.T) {
+	now := time.Now().UTC()
+	notBefore := now.Add(-2 * time.Hour).Format(time.RFC3339)
+	notOnOrAfter := now.Add(-time.Hour).Format(time.RFC3339)
+
+	_, err := parseSAMLServiceResponse(samlEnvelopeBody(notBefore, notOnOrAfter))
+	if err == nil {
+		t.Fatal("parseSAMLServiceResponse() error = nil, want error for expired assertion")
+	}
+}
+
+func TestParseSAMLServiceResponseAtNotOnOrAfterBoundary(t *testing.T) {
+	// "NotOnOrAfter" means the assertion is invalid exactly at that instant,
+	// not only after it. The parser compares against time.Now(), so the exact
+	// instant cannot be hit here; this checks from just past the boundary,
+	// one second in the past, so the result does not depend on execution time.
+	now := time.Now().UTC()
+	notBefore := now.Add(-2 * time.Hour).Format(time.RFC3339)
+	notOnOrAfter := now.Add(-time.Second).Format(time.RFC3339)
+
+	_, err := parseSAMLServiceResponse(samlEnvelopeBody(notBefore, notOnOrAfter))
+	if err == nil {
+		t.Fatal("parseSAMLServiceResponse() error = nil, want error at NotOnOrAfter boundary")
+	}
+}
+
+func TestParseSAMLServiceResponseNoNameIdentifier(t *testing.T) {
+	body := []byte(`<SOAP-ENV:Envelope xmlns:SOAP-ENV="http://schemas.xmlsoap.org/soap/envelope/">
+  <SOAP-ENV:Body>
+    <Response xmlns="urn:oasis:names:tc:SAML:1.0:protocol">
+      <Assertion xmlns="urn:oasis:names:tc:SAML:1.0:assertion">
+        <AuthenticationStatement><Subject></Subject></AuthenticationStatement>
+      </Assertion>
+    </Response>
+  </SOAP-ENV:Body>
+</SOAP-ENV:Envelope>`)
+
+	_, err := parseSAMLServiceResponse(body)
+	if err == nil {
+		t.Fatal("parseSAMLServiceResponse() error = nil, want error for missing NameIdentifier")
+	}
+}
+
+func TestParseSAMLServiceResponseMalformed(t *testing.T) {
+	_, err := parseSAMLServiceResponse([]byte(`<SOAP-ENV:Envelope>`))
+	if err == nil {
+		t.Fatal("parseSAMLServiceResponse() error = nil, want parse error for malformed XML")
+	}
+}
+
+func TestSAMLRequestEnvelopeEscapesTicket(t *testing.T) {
+	// A ticket containing XML/SOAP markup must not be able to break out of
+	// the <samlp:AssertionArtifact> element it is spliced into.
+	ticket := `ST-1</samlp:AssertionArtifact></samlp:Request></SOAP-ENV:Body></SOAP-ENV:Envelope><injected>pwn</injected>`
+
+	requestBody, err := samlRequestEnvelope(ticket)
+	if err != nil {
+		t.Fatalf("samlRequestEnvelope() error = %v", err)
+	}
+
+	if strings.Contains(requestBody, "<injected>") {
+		t.Fatalf("request body contains unescaped injection:\n%s", requestBody)
+	}
+
+	if !strings.Contains(requestBody, "ST-1&lt;/samlp:AssertionArtifact&gt;") {
+		t.Fatalf("request body does not contain escaped ticket:\n%s", requestBody)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
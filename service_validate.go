@@ -1,100 +1,214 @@
 package cas
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"path"
 	"strings"
-
-	"github.com/golang/glog"
+	"time"
 )
 
+const defaultUserAgent = "Golang CAS client gopkg.in/cas"
+
+// NewServiceTicketValidator creates a ServiceTicketValidator that probes the
+// CAS server for the highest protocol version it supports, preferring
+// CAS 3.0, then falling back to CAS 2.0 and CAS 1.0.
 func NewServiceTicketValidator(client *http.Client, casUrl *url.URL) *ServiceTicketValidator {
+	return NewServiceTicketValidatorWithProtocol(client, casUrl, ProtocolVersionUnspecified)
+}
+
+// NewServiceTicketValidatorWithProtocol creates a ServiceTicketValidator
+// pinned to the given CAS protocol version. Passing
+// ProtocolVersionUnspecified restores the default probing behaviour.
+func NewServiceTicketValidatorWithProtocol(client *http.Client, casUrl *url.URL, protocol ProtocolVersion) *ServiceTicketValidator {
 	return &ServiceTicketValidator{
-		client: client,
-		casUrl: casUrl,
+		client:   client,
+		casUrl:   casUrl,
+		protocol: protocol,
+		logger:   nopLogger{},
+		tracer:   nopTracer{},
 	}
 }
 
 // ServiceTicketValidator is responsible for the validation of a service ticket
 type ServiceTicketValidator struct {
-	client *http.Client
-	casUrl *url.URL
+	client     *http.Client
+	casUrl     *url.URL
+	protocol   ProtocolVersion
+	jsonFormat bool
+
+	userAgent string
+	timeout   time.Duration
+
+	retryAttempts int
+	retryBase     time.Duration
+	retryMax      time.Duration
+
+	cache *ticketCache
+
+	logger Logger
+	tracer Tracer
+}
+
+// UseJSONFormat requests the JSON response format from the CAS server,
+// instead of the default XML, for every subsequent validation. JSON is only
+// available on the CAS 2.0/3.0 serviceValidate endpoints.
+func (validator *ServiceTicketValidator) UseJSONFormat(enabled bool) {
+	validator.jsonFormat = enabled
+}
+
+func (validator *ServiceTicketValidator) userAgentHeader() string {
+	if validator.userAgent != "" {
+		return validator.userAgent
+	}
+
+	return defaultUserAgent
+}
+
+func (validator *ServiceTicketValidator) log() Logger {
+	if validator.logger != nil {
+		return validator.logger
+	}
+
+	return nopLogger{}
+}
+
+func (validator *ServiceTicketValidator) trace() Tracer {
+	if validator.tracer != nil {
+		return validator.tracer
+	}
+
+	return nopTracer{}
 }
 
-// ValidateTicket validates the service ticket for the given server. The method will try to use the service validate
-// endpoint of the cas >= 2 protocol, if the service validate endpoint not available, the function will use the cas 1
-// validate endpoint.
+// ValidateTicket validates the service ticket for the given server. It is a
+// thin wrapper around ValidateTicketContext using context.Background().
 func (validator *ServiceTicketValidator) ValidateTicket(serviceUrl *url.URL, ticket string) (*AuthenticationResponse, error) {
-	if glog.V(2) {
-		glog.Infof("Validating ticket %v for service %v", ticket, serviceUrl)
+	return validator.ValidateTicketContext(context.Background(), serviceUrl, ticket)
+}
+
+// ValidateTicketContext validates the service ticket for the given server,
+// honouring ctx's deadline and cancellation. Unless the validator was
+// pinned to a specific protocol version via NewServiceTicketValidatorWithProtocol,
+// the method tries the CAS 3.0 /p3/serviceValidate endpoint first, falls
+// back to the CAS 2.0 /serviceValidate endpoint if that is not available,
+// and finally falls back to the CAS 1.0 /validate endpoint.
+func (validator *ServiceTicketValidator) ValidateTicketContext(ctx context.Context, serviceUrl *url.URL, ticket string) (*AuthenticationResponse, error) {
+	ctx, span := validator.trace().Start(ctx, "cas.ValidateTicket")
+	defer span.End()
+	ctx = withSpan(ctx, span)
+
+	span.SetAttribute("cas.protocol", protocolLabel(validator.protocol))
+	span.SetAttribute("cas.service", serviceUrl.String())
+
+	validator.log().Debug("validating ticket", "ticket", ticket, "service", serviceUrl.String(), "cas_url", validator.casUrl.String())
+
+	if validator.cache != nil {
+		if success, err, ok := validator.cache.get(ticket); ok {
+			return success, err
+		}
 	}
 
-	u, err := validator.ServiceValidateUrl(serviceUrl, ticket)
+	success, err := validator.validateTicket(ctx, serviceUrl, ticket)
 	if err != nil {
-		return nil, err
+		span.RecordError(err)
 	}
 
-	r, err := http.NewRequest("GET", u, nil)
-	if err != nil {
-		return nil, err
+	if validator.cache != nil {
+		validator.cache.put(ticket, success, err)
 	}
 
-	r.Header.Add("User-Agent", "Golang CAS client gopkg.in/cas")
+	return success, err
+}
 
-	if glog.V(2) {
-		glog.Infof("Attempting ticket validation with %v", r.URL)
+func (validator *ServiceTicketValidator) validateTicket(ctx context.Context, serviceUrl *url.URL, ticket string) (*AuthenticationResponse, error) {
+	switch validator.protocol {
+	case ProtocolVersionCAS1:
+		return validator.validateTicketCas1(ctx, serviceUrl, ticket)
+	case ProtocolVersionCAS2:
+		return validator.validateTicketServiceValidate(ctx, serviceUrl, ticket, false)
+	case ProtocolVersionCAS3:
+		return validator.validateTicketServiceValidate(ctx, serviceUrl, ticket, true)
+	default:
+		return validator.validateTicketServiceValidate(ctx, serviceUrl, ticket, true)
 	}
+}
 
-	resp, err := validator.client.Do(r)
+// validateTicketServiceValidate performs CAS 2.0/3.0 ticket validation.
+// preferCas3 selects /p3/serviceValidate over /serviceValidate. When the
+// validator is not pinned to a protocol version and the server does not
+// expose the requested endpoint, it falls back from /p3/serviceValidate to
+// /serviceValidate and from /serviceValidate to CAS 1.0; a pinned validator
+// reports the 404 as an error instead.
+func (validator *ServiceTicketValidator) validateTicketServiceValidate(ctx context.Context, serviceUrl *url.URL, ticket string, preferCas3 bool) (*AuthenticationResponse, error) {
+	u, err := validator.serviceValidateUrl(serviceUrl, ticket, preferCas3)
 	if err != nil {
 		return nil, err
 	}
 
-	if glog.V(2) {
-		glog.Infof("Request %v %v returned %v",
-			r.Method, r.URL,
-			resp.Status)
+	r, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return nil, err
 	}
 
-	if resp.StatusCode == http.StatusNotFound {
-		return validator.validateTicketCas1(serviceUrl, ticket)
+	if validator.jsonFormat {
+		r.Header.Add("Accept", "application/json")
 	}
 
-	body, err := ioutil.ReadAll(resp.Body)
-	resp.Body.Close()
-
+	resp, body, err := validator.do(r)
 	if err != nil {
 		return nil, err
 	}
 
+	if resp.StatusCode == http.StatusNotFound && validator.protocol == ProtocolVersionUnspecified {
+		// /p3/serviceValidate is only present on CAS >= 3.0 deployments;
+		// fall back to /serviceValidate before giving up on CAS 1.0.
+		if preferCas3 {
+			return validator.validateTicketServiceValidate(ctx, serviceUrl, ticket, false)
+		}
+
+		return validator.validateTicketCas1(ctx, serviceUrl, ticket)
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("cas: validate ticket: %v", string(body))
 	}
 
-	if glog.V(2) {
-		glog.Infof("Received authentication response\n%v", string(body))
-	}
+	validator.log().Debug("received authentication response", "status", resp.StatusCode)
 
-	body = []byte(strings.Replace(string(body), "[Etc/UTC]", "", -1))
-	success, err := ParseServiceResponse(body)
+	var success *AuthenticationResponse
+	if validator.jsonFormat {
+		success, err = parseJSONServiceResponse(body)
+	} else {
+		body = []byte(strings.Replace(string(body), "[Etc/UTC]", "", -1))
+		success, err = ParseServiceResponse(body)
+	}
 	if err != nil {
+		validator.log().Error("failed to parse service response", "error", err)
 		return nil, err
 	}
 
-	if glog.V(2) {
-		glog.Infof("Parsed ServiceResponse: %#v", success)
-	}
-
 	return success, nil
 }
 
 // ServiceValidateUrl creates the service validation url for the cas >= 2 protocol.
 // TODO the function is only exposed, because of the clients ServiceValidateUrl function
 func (validator *ServiceTicketValidator) ServiceValidateUrl(serviceUrl *url.URL, ticket string) (string, error) {
-	u, err := validator.casUrl.Parse(path.Join(validator.casUrl.Path, "serviceValidate"))
+	return validator.serviceValidateUrl(serviceUrl, ticket, false)
+}
+
+// serviceValidateUrl creates the service validation url, targeting
+// /p3/serviceValidate when cas3 is true and /serviceValidate otherwise.
+func (validator *ServiceTicketValidator) serviceValidateUrl(serviceUrl *url.URL, ticket string, cas3 bool) (string, error) {
+	endpoint := "serviceValidate"
+	if cas3 {
+		endpoint = path.Join("p3", "serviceValidate")
+	}
+
+	u, err := validator.casUrl.Parse(path.Join(validator.casUrl.Path, endpoint))
 	if err != nil {
 		return "", err
 	}
@@ -102,42 +216,26 @@ func (validator *ServiceTicketValidator) ServiceValidateUrl(serviceUrl *url.URL,
 	q := u.Query()
 	q.Add("service", sanitisedURLString(serviceUrl))
 	q.Add("ticket", ticket)
+	if validator.jsonFormat {
+		q.Add("format", "JSON")
+	}
 	u.RawQuery = q.Encode()
 
 	return u.String(), nil
 }
 
-func (validator *ServiceTicketValidator) validateTicketCas1(serviceUrl *url.URL, ticket string) (*AuthenticationResponse, error) {
+func (validator *ServiceTicketValidator) validateTicketCas1(ctx context.Context, serviceUrl *url.URL, ticket string) (*AuthenticationResponse, error) {
 	u, err := validator.ValidateUrl(serviceUrl, ticket)
 	if err != nil {
 		return nil, err
 	}
 
-	r, err := http.NewRequest("GET", u, nil)
+	r, err := http.NewRequestWithContext(ctx, "GET", u, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	r.Header.Add("User-Agent", "Golang CAS client gopkg.in/cas")
-
-	if glog.V(2) {
-		glog.Infof("Attempting ticket validation with %v", r.URL)
-	}
-
-	resp, err := validator.client.Do(r)
-	if err != nil {
-		return nil, err
-	}
-
-	if glog.V(2) {
-		glog.Infof("Request %v %v returned %v",
-			r.Method, r.URL,
-			resp.Status)
-	}
-
-	data, err := ioutil.ReadAll(resp.Body)
-	resp.Body.Close()
-
+	resp, data, err := validator.do(r)
 	if err != nil {
 		return nil, err
 	}
@@ -148,20 +246,15 @@ func (validator *ServiceTicketValidator) validateTicketCas1(serviceUrl *url.URL,
 		return nil, fmt.Errorf("cas: validate ticket: %v", body)
 	}
 
-	if glog.V(2) {
-		glog.Infof("Received authentication response\n%v", body)
-	}
+	validator.log().Debug("received authentication response", "status", resp.StatusCode)
 
 	if body == "no\n\n" {
 		return nil, nil // not logged in
 	}
 
 	success := &AuthenticationResponse{
-		User: body[4 : len(body)-1],
-	}
-
-	if glog.V(2) {
-		glog.Infof("Parsed ServiceResponse: %#v", success)
+		User:    body[4 : len(body)-1],
+		Success: true,
 	}
 
 	return success, nil
@@ -182,3 +275,101 @@ func (validator *ServiceTicketValidator) ValidateUrl(serviceUrl *url.URL, ticket
 
 	return u.String(), nil
 }
+
+// do sends r through the validator's retry/timeout middleware, reading and
+// returning the full response body. The returned *http.Response has its
+// Body already closed.
+func (validator *ServiceTicketValidator) do(r *http.Request) (*http.Response, []byte, error) {
+	r.Header.Add("User-Agent", validator.userAgentHeader())
+
+	if validator.timeout > 0 {
+		ctx, cancel := context.WithTimeout(r.Context(), validator.timeout)
+		defer cancel()
+		r = r.WithContext(ctx)
+	}
+
+	validator.log().Debug("attempting ticket validation", "url", r.URL.String())
+
+	resp, err := validator.doWithRetry(r)
+	if err != nil {
+		validator.log().Error("ticket validation request failed", "url", r.URL.String(), "error", err)
+		return nil, nil, err
+	}
+
+	spanFromContext(r.Context()).SetAttribute("cas.status_code", resp.StatusCode)
+
+	validator.log().Debug("ticket validation request returned", "url", r.URL.String(), "status", resp.StatusCode)
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return resp, body, nil
+}
+
+// doWithRetry executes r, retrying on network errors and 5xx responses with
+// exponential backoff, up to the validator's configured retry attempts. With
+// no retry configured, it makes a single validator.client.Do(r). A 5xx
+// response on the last attempt is closed and returned as an error, not as a
+// response.
+func (validator *ServiceTicketValidator) doWithRetry(r *http.Request) (*http.Response, error) {
+	attempts := validator.retryAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			if r.GetBody != nil {
+				body, err := r.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				r.Body = body
+			}
+
+			timer := time.NewTimer(retryBackoff(attempt, validator.retryBase, validator.retryMax))
+			select {
+			case <-r.Context().Done():
+				timer.Stop()
+				return nil, r.Context().Err()
+			case <-timer.C:
+			}
+		}
+
+		resp, err := validator.client.Do(r)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		if resp.StatusCode >= http.StatusInternalServerError {
+			resp.Body.Close()
+			lastErr = fmt.Errorf("cas: server error: %v", resp.Status)
+			continue
+		}
+
+		return resp, nil
+	}
+
+	return nil, lastErr
+}
+
+// retryBackoff returns the exponential backoff delay before the given retry
+// attempt (1-indexed), doubling from base and capped at max. A non-positive
+// base disables the delay.
+func retryBackoff(attempt int, base, max time.Duration) time.Duration {
+	if base <= 0 {
+		return 0
+	}
+
+	d := base << uint(attempt-1)
+	if max > 0 && d > max {
+		return max
+	}
+
+	return d
+}
@@ -0,0 +1,127 @@
+package cas
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestRetryBackoff(t *testing.T) {
+	tests := []struct {
+		name    string
+		attempt int
+		base    time.Duration
+		max     time.Duration
+		want    time.Duration
+	}{
+		{"no base disables delay", 1, 0, time.Second, 0},
+		{"first retry uses base", 1, time.Millisecond, 0, time.Millisecond},
+		{"doubles each attempt", 3, time.Millisecond, 0, 4 * time.Millisecond},
+		{"capped at max", 10, time.Millisecond, 5 * time.Millisecond, 5 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryBackoff(tt.attempt, tt.base, tt.max); got != tt.want {
+				t.Errorf("retryBackoff(%d, %v, %v) = %v, want %v", tt.attempt, tt.base, tt.max, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDoWithRetryExhaustsAttemptsOn5xx(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	casUrl, _ := url.Parse(server.URL)
+	validator := NewServiceTicketValidatorWithOptions(server.Client(), casUrl, WithRetry(3, time.Millisecond, 0))
+
+	r, err := http.NewRequestWithContext(context.Background(), "GET", server.URL, nil)
+	if err != nil {
+		t.Fatalf("NewRequestWithContext() error = %v", err)
+	}
+
+	_, err = validator.doWithRetry(r)
+	if err == nil {
+		t.Fatal("doWithRetry() error = nil, want error after exhausting retries")
+	}
+
+	if requests != 3 {
+		t.Errorf("requests = %d, want 3", requests)
+	}
+}
+
+func TestDoWithRetrySucceedsAfterTransientFailure(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests < 2 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	casUrl, _ := url.Parse(server.URL)
+	validator := NewServiceTicketValidatorWithOptions(server.Client(), casUrl, WithRetry(3, time.Millisecond, 0))
+
+	r, err := http.NewRequestWithContext(context.Background(), "GET", server.URL, nil)
+	if err != nil {
+		t.Fatalf("NewRequestWithContext() error = %v", err)
+	}
+
+	resp, err := validator.doWithRetry(r)
+	if err != nil {
+		t.Fatalf("doWithRetry() error = %v, want nil after transient failure recovers", err)
+	}
+	resp.Body.Close()
+
+	if requests != 2 {
+		t.Errorf("requests = %d, want 2", requests)
+	}
+}
+
+func TestDoWithRetryStopsOnContextCancellation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	casUrl, _ := url.Parse(server.URL)
+	validator := NewServiceTicketValidatorWithOptions(server.Client(), casUrl, WithRetry(5, time.Hour, 0))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r, err := http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+	if err != nil {
+		t.Fatalf("NewRequestWithContext() error = %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := validator.doWithRetry(r)
+		done <- err
+	}()
+
+	// Let the first attempt fail and enter the hour-long backoff, then
+	// cancel: doWithRetry must return promptly instead of waiting out the
+	// backoff, per the context-aware retry fix.
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Errorf("doWithRetry() error = %v, want context.Canceled", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("doWithRetry() did not return promptly after context cancellation")
+	}
+}
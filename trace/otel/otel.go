@@ -0,0 +1,67 @@
+// Package otel adapts go.opentelemetry.io/otel to the cas.Tracer interface.
+package otel
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/inforix/cas-client-go"
+)
+
+// Tracer wraps an OpenTelemetry trace.Tracer as a cas.Tracer. Construct the
+// underlying tracer with otel.Tracer("..."), and inject the result via
+// cas.WithTracer.
+type Tracer struct {
+	tracer oteltrace.Tracer
+}
+
+// New returns a cas.Tracer backed by tracer.
+func New(tracer oteltrace.Tracer) *Tracer {
+	return &Tracer{tracer: tracer}
+}
+
+func (t *Tracer) Start(ctx context.Context, name string) (context.Context, cas.Span) {
+	ctx, span := t.tracer.Start(ctx, name)
+	return ctx, &spanAdapter{span: span}
+}
+
+type spanAdapter struct {
+	span oteltrace.Span
+}
+
+func (s *spanAdapter) SetAttribute(key string, value interface{}) {
+	s.span.SetAttributes(toAttribute(key, value))
+}
+
+func (s *spanAdapter) RecordError(err error) {
+	s.span.RecordError(err)
+}
+
+func (s *spanAdapter) End() {
+	s.span.End()
+}
+
+func toAttribute(key string, value interface{}) attribute.KeyValue {
+	switch v := value.(type) {
+	case string:
+		return attribute.String(key, v)
+	case bool:
+		return attribute.Bool(key, v)
+	case int:
+		return attribute.Int(key, v)
+	case int64:
+		return attribute.Int64(key, v)
+	case float64:
+		return attribute.Float64(key, v)
+	default:
+		return attribute.String(key, fmt.Sprint(v))
+	}
+}
+
+var (
+	_ cas.Tracer = (*Tracer)(nil)
+	_ cas.Span   = (*spanAdapter)(nil)
+)
@@ -0,0 +1,64 @@
+package cas
+
+import "context"
+
+// Span is the minimal span surface ServiceTicketValidator needs around a
+// validation attempt.
+type Span interface {
+	SetAttribute(key string, value interface{})
+	RecordError(err error)
+	End()
+}
+
+// Tracer starts a Span around each validation attempt. Inject an
+// implementation via WithTracer; by default ServiceTicketValidator does no
+// tracing. See cas/trace/otel for an OpenTelemetry-backed Tracer.
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// nopTracer is the default Tracer: it starts no-op spans and requires no
+// tracing dependency.
+type nopTracer struct{}
+
+func (nopTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	return ctx, nopSpan{}
+}
+
+type nopSpan struct{}
+
+func (nopSpan) SetAttribute(string, interface{}) {}
+func (nopSpan) RecordError(error)                {}
+func (nopSpan) End()                             {}
+
+type spanContextKey struct{}
+
+// withSpan attaches span to ctx so spanFromContext can retrieve it deeper in
+// the request path, without requiring the Tracer's own context propagation.
+func withSpan(ctx context.Context, span Span) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, span)
+}
+
+// spanFromContext returns the span attached by withSpan, or a no-op span if
+// ctx carries none, so callers can unconditionally set attributes.
+func spanFromContext(ctx context.Context) Span {
+	if span, ok := ctx.Value(spanContextKey{}).(Span); ok {
+		return span
+	}
+
+	return nopSpan{}
+}
+
+// protocolLabel returns the cas.protocol span attribute value for protocol.
+func protocolLabel(protocol ProtocolVersion) string {
+	switch protocol {
+	case ProtocolVersionCAS1:
+		return "CAS1"
+	case ProtocolVersionCAS2:
+		return "CAS2"
+	case ProtocolVersionCAS3:
+		return "CAS3"
+	default:
+		return "unspecified"
+	}
+}
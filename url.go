@@ -0,0 +1,15 @@
+package cas
+
+import (
+	"net/url"
+)
+
+// sanitisedURLString returns a URL as a string, suitable for inclusion as the
+// value of a "service" query parameter.
+func sanitisedURLString(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+
+	return u.String()
+}